@@ -0,0 +1,158 @@
+package pipe
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+// RV wraps a Go value as a reflect.Value, the common return type every pipe
+// in this package produces.
+func RV(v any) reflect.Value {
+	return reflect.ValueOf(v)
+}
+
+// deref follows interface/pointer indirection down to the concrete value,
+// so the coercion helpers below work the same whether a pipe receives a
+// bare value or one boxed by text/template's pipeline machinery.
+func deref(v reflect.Value) reflect.Value {
+	for v.Kind() == reflect.Interface || v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return reflect.Value{}
+		}
+		v = v.Elem()
+	}
+	return v
+}
+
+// toFloatRV coerces a reflect.Value to float64, accepting ints, uints,
+// floats, bools, and numeric strings. Unparseable or invalid values
+// coerce to 0, matching the silent-fallback behavior of Thousand.
+func toFloatRV(v reflect.Value) float64 {
+	v = deref(v)
+	if !v.IsValid() {
+		return 0
+	}
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		return v.Float()
+	case reflect.Bool:
+		if v.Bool() {
+			return 1
+		}
+		return 0
+	case reflect.String:
+		f, err := strconv.ParseFloat(v.String(), 64)
+		if err != nil {
+			return 0
+		}
+		return f
+	default:
+		return 0
+	}
+}
+
+// tryFloat reports whether v coerces to a number (as opposed to toFloatRV,
+// which silently returns 0 for non-numeric input), for callers that need
+// to tell "zero" apart from "not a number".
+func tryFloat(v reflect.Value) (float64, bool) {
+	v = deref(v)
+	if !v.IsValid() {
+		return 0, false
+	}
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	case reflect.String:
+		f, err := strconv.ParseFloat(v.String(), 64)
+		if err != nil {
+			return 0, false
+		}
+		return f, true
+	default:
+		return 0, false
+	}
+}
+
+// toIntRV coerces a reflect.Value to int64 via toFloatRV, truncating any
+// fractional part.
+func toIntRV(v reflect.Value) int64 {
+	return int64(toFloatRV(v))
+}
+
+// toStringRV coerces a reflect.Value to its string representation. This is
+// the inverse of toFloatRV: numbers are formatted back to decimal, and
+// anything else falls back to fmt.Sprint.
+func toStringRV(v reflect.Value) string {
+	v = deref(v)
+	if !v.IsValid() {
+		return ""
+	}
+	switch v.Kind() {
+	case reflect.String:
+		return v.String()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10)
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'f', -1, 64)
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool())
+	default:
+		return fmt.Sprint(v.Interface())
+	}
+}
+
+// toBoolRV coerces a reflect.Value to bool. Zero values (0, "", nil, and
+// empty slices/maps/arrays) are false; everything else is true.
+func toBoolRV(v reflect.Value) bool {
+	v = deref(v)
+	if !v.IsValid() {
+		return false
+	}
+	switch v.Kind() {
+	case reflect.Bool:
+		return v.Bool()
+	case reflect.String:
+		return v.String() != ""
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return v.Len() > 0
+	default:
+		return toFloatRV(v) != 0
+	}
+}
+
+// toSliceRV coerces a reflect.Value holding a slice or array into
+// []reflect.Value, so collection pipes don't need a type switch on every
+// possible element type. Anything else yields an empty slice.
+func toSliceRV(v reflect.Value) []reflect.Value {
+	v = deref(v)
+	if !v.IsValid() || (v.Kind() != reflect.Slice && v.Kind() != reflect.Array) {
+		return nil
+	}
+	out := make([]reflect.Value, v.Len())
+	for i := range out {
+		out[i] = v.Index(i)
+	}
+	return out
+}
+
+// toStringSliceRV coerces a reflect.Value holding a slice/array into
+// []string using toStringRV element-by-element.
+func toStringSliceRV(v reflect.Value) []string {
+	elems := toSliceRV(v)
+	out := make([]string, len(elems))
+	for i, e := range elems {
+		out[i] = toStringRV(e)
+	}
+	return out
+}
@@ -0,0 +1,223 @@
+package pipe
+
+import (
+	"reflect"
+	"sort"
+)
+
+// First returns the first element of a slice, or an invalid reflect.Value
+// if the slice is empty.
+func First(vals ...reflect.Value) reflect.Value {
+	if len(vals) == 0 {
+		return reflect.Value{}
+	}
+	elems := toSliceRV(vals[len(vals)-1])
+	if len(elems) == 0 {
+		return reflect.Value{}
+	}
+	return elems[0]
+}
+
+// Last returns the last element of a slice, or an invalid reflect.Value if
+// the slice is empty.
+func Last(vals ...reflect.Value) reflect.Value {
+	if len(vals) == 0 {
+		return reflect.Value{}
+	}
+	elems := toSliceRV(vals[len(vals)-1])
+	if len(elems) == 0 {
+		return reflect.Value{}
+	}
+	return elems[len(elems)-1]
+}
+
+// Len returns the length of a slice, array, map, or string.
+func Len(vals ...reflect.Value) reflect.Value {
+	if len(vals) == 0 {
+		return RV(int64(0))
+	}
+	v := deref(vals[len(vals)-1])
+	if !v.IsValid() {
+		return RV(int64(0))
+	}
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map, reflect.String:
+		return RV(int64(v.Len()))
+	default:
+		return RV(int64(0))
+	}
+}
+
+// Reverse returns a copy of the slice with elements in reverse order.
+func Reverse(vals ...reflect.Value) reflect.Value {
+	if len(vals) == 0 {
+		return RV([]any{})
+	}
+	elems := toSliceRV(vals[len(vals)-1])
+	out := make([]any, len(elems))
+	for i, e := range elems {
+		out[len(elems)-1-i] = e.Interface()
+	}
+	return RV(out)
+}
+
+// Sort returns a sorted copy of the slice. Elements are compared
+// numerically if every element is a Go int, uint, or float value,
+// otherwise (numeric strings included) lexicographically as strings.
+func Sort(vals ...reflect.Value) reflect.Value {
+	if len(vals) == 0 {
+		return RV([]any{})
+	}
+	elems := toSliceRV(vals[len(vals)-1])
+	out := make([]any, len(elems))
+	for i, e := range elems {
+		out[i] = e.Interface()
+	}
+	if allNumeric(elems) {
+		sort.Slice(out, func(i, j int) bool {
+			return toFloatRV(reflect.ValueOf(out[i])) < toFloatRV(reflect.ValueOf(out[j]))
+		})
+	} else {
+		sort.Slice(out, func(i, j int) bool {
+			return toStringRV(reflect.ValueOf(out[i])) < toStringRV(reflect.ValueOf(out[j]))
+		})
+	}
+	return RV(out)
+}
+
+func allNumeric(elems []reflect.Value) bool {
+	for _, e := range elems {
+		switch deref(e).Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+			reflect.Float32, reflect.Float64:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// Uniq returns a copy of the slice with duplicate elements removed,
+// preserving the first occurrence of each value.
+func Uniq(vals ...reflect.Value) reflect.Value {
+	if len(vals) == 0 {
+		return RV([]any{})
+	}
+	elems := toSliceRV(vals[len(vals)-1])
+	seen := map[string]bool{}
+	out := make([]any, 0, len(elems))
+	for _, e := range elems {
+		key := toStringRV(e)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		out = append(out, e.Interface())
+	}
+	return RV(out)
+}
+
+// Map applies a registered pipe, named by vals[0], to every element of the
+// slice and returns the results. Extra args between the name and the slice
+// (vals[1:len(vals)-1]) are passed to the pipe ahead of each element, the
+// same way they would be written in a template pipeline.
+func Map(vals ...reflect.Value) reflect.Value {
+	if len(vals) < 2 {
+		return RV([]any{})
+	}
+	fn, ok := lookupPipe(toStringRV(vals[0]))
+	if !ok {
+		return RV([]any{})
+	}
+	extra := vals[1 : len(vals)-1]
+	elems := toSliceRV(vals[len(vals)-1])
+	out := make([]any, len(elems))
+	for i, e := range elems {
+		args := append(append([]reflect.Value{}, extra...), e)
+		out[i] = callPipe(fn, args).Interface()
+	}
+	return RV(out)
+}
+
+// Filter keeps only the elements of the slice for which the registered
+// pipe named by vals[0] returns a truthy value, in the style of Map.
+func Filter(vals ...reflect.Value) reflect.Value {
+	if len(vals) < 2 {
+		return RV([]any{})
+	}
+	fn, ok := lookupPipe(toStringRV(vals[0]))
+	if !ok {
+		return RV([]any{})
+	}
+	extra := vals[1 : len(vals)-1]
+	elems := toSliceRV(vals[len(vals)-1])
+	out := make([]any, 0, len(elems))
+	for _, e := range elems {
+		args := append(append([]reflect.Value{}, extra...), e)
+		if toBoolRV(callPipe(fn, args)) {
+			out = append(out, e.Interface())
+		}
+	}
+	return RV(out)
+}
+
+// Reduce folds the slice down to a single value using the registered pipe
+// named by vals[0], starting from the accumulator vals[1].
+func Reduce(vals ...reflect.Value) reflect.Value {
+	if len(vals) < 3 {
+		return reflect.Value{}
+	}
+	fn, ok := lookupPipe(toStringRV(vals[0]))
+	if !ok {
+		return reflect.Value{}
+	}
+	acc := vals[1]
+	elems := toSliceRV(vals[len(vals)-1])
+	for _, e := range elems {
+		acc = callPipe(fn, []reflect.Value{acc, e})
+	}
+	return acc
+}
+
+// Range returns a slice of int64 counting from vals[0] up to (exclusive)
+// vals[1], stepping by the optional vals[2] (default 1).
+func Range(vals ...reflect.Value) reflect.Value {
+	if len(vals) < 2 {
+		return RV([]int64{})
+	}
+	start := toIntRV(vals[0])
+	end := toIntRV(vals[1])
+	step := int64(1)
+	if len(vals) > 2 {
+		step = toIntRV(vals[2])
+	}
+	if step == 0 {
+		return RV([]int64{})
+	}
+	out := []int64{}
+	if step > 0 {
+		for i := start; i < end; i += step {
+			out = append(out, i)
+		}
+	} else {
+		for i := start; i > end; i += step {
+			out = append(out, i)
+		}
+	}
+	return RV(out)
+}
+
+// Index returns the element at position vals[0] of the slice, or an
+// invalid reflect.Value if the index is out of range.
+func Index(vals ...reflect.Value) reflect.Value {
+	if len(vals) < 2 {
+		return reflect.Value{}
+	}
+	i := int(toIntRV(vals[0]))
+	elems := toSliceRV(vals[len(vals)-1])
+	if i < 0 || i >= len(elems) {
+		return reflect.Value{}
+	}
+	return elems[i]
+}
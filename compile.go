@@ -0,0 +1,180 @@
+package pipe
+
+import (
+	"bytes"
+	"fmt"
+	"hash/fnv"
+	"html/template"
+	"io"
+	"strings"
+	"sync"
+)
+
+// PreprocessError is returned by Compile when an expression can't be
+// rewritten into a valid pipeline: an unterminated "{{", an unmatched
+// ternary '?'/':', an unknown operator, or a malformed expression that
+// leaves the RPN stack unbalanced. Offset is the byte offset into the
+// template where the offending "{{" starts, and Excerpt is a short
+// snippet of the template around it, for error messages that point at
+// something useful instead of just "parse error".
+type PreprocessError struct {
+	Offset  int
+	Excerpt string
+	Msg     string
+}
+
+func (e *PreprocessError) Error() string {
+	return fmt.Sprintf("pipe: preprocess error at offset %d: %s (near %q)", e.Offset, e.Msg, e.Excerpt)
+}
+
+// excerptAround returns a short snippet of tmpl (up to 20 bytes either
+// side of offset, newlines kept as-is), for use in a PreprocessError.
+func excerptAround(tmpl string, offset int) string {
+	const radius = 20
+	start := offset - radius
+	if start < 0 {
+		start = 0
+	}
+	end := offset + radius
+	if end > len(tmpl) {
+		end = len(tmpl)
+	}
+	return tmpl[start:end]
+}
+
+// preprocessStrict mirrors Preprocessor, but instead of silently falling
+// back to the original expression on a parse failure, it returns a
+// *PreprocessError identifying where the failure happened.
+func preprocessStrict(tmpl string) (string, error) {
+	start := 0
+	var result strings.Builder
+
+	for start < len(tmpl) {
+		idx := strings.Index(tmpl[start:], "{{")
+		if idx == -1 {
+			result.WriteString(tmpl[start:])
+			break
+		}
+		result.WriteString(tmpl[start : start+idx])
+		openAt := start + idx
+
+		endIdx := strings.Index(tmpl[openAt:], "}}")
+		if endIdx == -1 {
+			return "", &PreprocessError{
+				Offset:  openAt,
+				Excerpt: excerptAround(tmpl, openAt),
+				Msg:     "unterminated {{",
+			}
+		}
+		closeAt := openAt + endIdx
+
+		expr := strings.TrimSpace(tmpl[openAt+2 : closeAt])
+		expr = replaceVars(expr)
+
+		// A bare call like `thousand "." 2 "," $x` also trips
+		// needsPreprocessing (its quotes aren't in the bare-var charset)
+		// but has no operator to apply precedence to, so it's excluded
+		// and passed through as an ordinary template action instead of
+		// being reported as malformed.
+		if needsPreprocessing(expr) && !isBareAction(expr) {
+			tokens := tokenize(expr)
+			tokens, err := parseTernaryE(tokens)
+			if err != nil {
+				return "", &PreprocessError{Offset: openAt, Excerpt: excerptAround(tmpl, openAt), Msg: err.Error()}
+			}
+			rpn := toRPN(tokens)
+			newExpr, err := rpnToPipelineE(rpn)
+			if err != nil {
+				return "", &PreprocessError{Offset: openAt, Excerpt: excerptAround(tmpl, openAt), Msg: err.Error()}
+			}
+			newExpr = strings.ReplaceAll(newExpr, "Vars.", "$.Vars.")
+			result.WriteString("{{ " + newExpr + " }}")
+		} else {
+			expr = strings.ReplaceAll(expr, "Vars.", "$.Vars.")
+			result.WriteString("{{ " + expr + " }}")
+		}
+
+		start = closeAt + 2
+	}
+	return result.String(), nil
+}
+
+// Template is a preprocessed, parsed template ready to execute. Obtain one
+// via Compile, which also caches the result by content hash.
+type Template struct {
+	tmpl *template.Template
+}
+
+// Execute applies the template to data and writes the result to w.
+func (t *Template) Execute(w io.Writer, data any) error {
+	return t.tmpl.Execute(w, data)
+}
+
+// ExecuteString applies the template to data and returns the result as a
+// string.
+func (t *Template) ExecuteString(data any) (string, error) {
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// compileCache holds already-compiled templates keyed by a content hash of
+// name+tmpl, so repeated Compile calls for the same template text (the
+// common case: a server compiling the same template on every request)
+// skip preprocessing and parsing entirely.
+var (
+	compileCacheMu sync.RWMutex
+	compileCache   = map[uint64]*Template{}
+)
+
+// contentHash hashes name+tmpl with FNV-1a, so the cache key depends only
+// on the name and text passed to Compile: the same pair always maps to
+// the same cached *Template.
+func contentHash(name, tmpl string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(name))
+	h.Write([]byte{0})
+	h.Write([]byte(tmpl))
+	return h.Sum64()
+}
+
+// Compile preprocesses tmpl, parses it into a *template.Template seeded
+// with New(), and caches the result keyed by a hash of name+tmpl, so
+// rendering the same template text repeatedly only pays the preprocessing
+// and parsing cost once. Preprocessing failures (unmatched ternary,
+// unknown operator, malformed expression, unterminated "{{") are returned
+// as a *PreprocessError instead of being silently swallowed. This covers
+// the top-level expression only: function-call arguments and ternary
+// branches are compiled by the lenient compileExpr, so a malformed one
+// is not reported as a *PreprocessError; its text is passed on to the
+// template parser, and any parse error is returned wrapped instead.
+func Compile(name, tmpl string) (*Template, error) {
+	key := contentHash(name, tmpl)
+
+	compileCacheMu.RLock()
+	if cached, ok := compileCache[key]; ok {
+		compileCacheMu.RUnlock()
+		return cached, nil
+	}
+	compileCacheMu.RUnlock()
+
+	processed, err := preprocessStrict(tmpl)
+	if err != nil {
+		return nil, err
+	}
+
+	parsed, err := template.New(name).Funcs(New()).Parse(processed)
+	if err != nil {
+		return nil, fmt.Errorf("pipe: parse %q: %w", name, err)
+	}
+
+	t := &Template{tmpl: parsed}
+
+	compileCacheMu.Lock()
+	compileCache[key] = t
+	compileCacheMu.Unlock()
+
+	return t, nil
+}
@@ -0,0 +1,53 @@
+package pipe
+
+import "testing"
+
+// benchTemplate is representative of a template with enough operators and
+// calls to make preprocessing (tokenize/shunting-yard/pipeline-emit) do
+// real work, so the benchmark reflects the cost Compile's cache avoids.
+const benchTemplate = `{{ $name | upper }}: {{ $a + $b * 2 - $c / 4 }}, {{ $score >= 50 ? "pass" : "fail" }}, {{ truncate(20, $bio) }}`
+
+// TestBenchTemplateRenders asserts benchTemplate itself still compiles and
+// renders correctly, so a preprocessor regression (e.g. "$name | upper"
+// silently miscompiling into a bitwise-or) fails a test instead of only
+// skewing a timing-only benchmark.
+func TestBenchTemplateRenders(t *testing.T) {
+	tpl, err := Compile("bench-assert", benchTemplate)
+	if err != nil {
+		t.Fatalf("Compile: %v", err)
+	}
+	out, err := tpl.ExecuteString(map[string]any{"Vars": map[string]any{
+		"name":  "bob",
+		"a":     10,
+		"b":     4,
+		"c":     8,
+		"score": 75,
+		"bio":   "a very long biography that should get truncated",
+	}})
+	if err != nil {
+		t.Fatalf("ExecuteString: %v", err)
+	}
+	const want = `BOB: 16, pass, a very long biograph...`
+	if out != want {
+		t.Fatalf("got %q, want %q", out, want)
+	}
+}
+
+// BenchmarkPreprocessEveryRender re-runs the full preprocessor on every
+// call, as code would if it called Preprocessor directly on each render
+// instead of compiling once with Compile.
+func BenchmarkPreprocessEveryRender(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		Preprocessor(benchTemplate)
+	}
+}
+
+// BenchmarkCompileCached calls Compile repeatedly with the same name and
+// template text, so after the first call every iteration is a cache hit.
+func BenchmarkCompileCached(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		if _, err := Compile("bench", benchTemplate); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
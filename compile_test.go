@@ -0,0 +1,35 @@
+package pipe
+
+import "testing"
+
+// TestCompileBareAction guards against Compile rejecting an ordinary
+// space-separated template action as a malformed expression: the
+// quotes in its arguments trip needsPreprocessing, but there's no
+// operator for rpnToPipelineE to combine them with.
+func TestCompileBareAction(t *testing.T) {
+	tpl, err := Compile("bare-action", `{{ thousand "." 2 "," $x }}`)
+	if err != nil {
+		t.Fatalf("Compile: %v", err)
+	}
+	if _, err := tpl.ExecuteString(map[string]any{"Vars": map[string]any{"x": 1234567.89}}); err != nil {
+		t.Fatalf("ExecuteString: %v", err)
+	}
+}
+
+// TestCompileNegativeLiteral guards against a '-' directly before a
+// digit, in operand position, being mistaken for the subtraction
+// operator instead of a negative literal's sign.
+func TestCompileNegativeLiteral(t *testing.T) {
+	tpl, err := Compile("neg-literal", `{{ $a - $b }}: {{ 2 - -3 }}`)
+	if err != nil {
+		t.Fatalf("Compile: %v", err)
+	}
+	out, err := tpl.ExecuteString(map[string]any{"Vars": map[string]any{"a": 10.0, "b": 4.0}})
+	if err != nil {
+		t.Fatalf("ExecuteString: %v", err)
+	}
+	const want = "6: 5"
+	if out != want {
+		t.Fatalf("got %q, want %q", out, want)
+	}
+}
@@ -0,0 +1,106 @@
+package pipe
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// Now returns the current local time.
+func Now(vals ...reflect.Value) reflect.Value {
+	return RV(time.Now())
+}
+
+// Date parses a value (a time.Time, a Unix timestamp, or an RFC3339
+// string) into a time.Time, or the zero time.Time if it can't be parsed.
+func Date(vals ...reflect.Value) reflect.Value {
+	if len(vals) == 0 {
+		return RV(time.Time{})
+	}
+	return RV(parseTime(vals[len(vals)-1]))
+}
+
+// DateFormat formats a date using vals[0] as a Go reference-time layout.
+func DateFormat(vals ...reflect.Value) reflect.Value {
+	if len(vals) < 2 {
+		return RV("")
+	}
+	t := parseTime(vals[len(vals)-1])
+	return RV(t.Format(toStringRV(vals[0])))
+}
+
+// parseTime coerces a reflect.Value into a time.Time, accepting a
+// time.Time value, a Unix timestamp (seconds), or an RFC3339 string.
+func parseTime(v reflect.Value) time.Time {
+	v = deref(v)
+	if !v.IsValid() {
+		return time.Time{}
+	}
+	if t, ok := v.Interface().(time.Time); ok {
+		return t
+	}
+	if v.Kind() == reflect.String {
+		if t, err := time.Parse(time.RFC3339, v.String()); err == nil {
+			return t
+		}
+		return time.Time{}
+	}
+	if f, ok := tryFloat(v); ok {
+		return time.Unix(int64(f), 0)
+	}
+	return time.Time{}
+}
+
+// Duration parses a Go duration string (e.g. "90m") into a time.Duration.
+// Invalid input yields a zero duration.
+func Duration(vals ...reflect.Value) reflect.Value {
+	if len(vals) == 0 {
+		return RV(time.Duration(0))
+	}
+	d, err := time.ParseDuration(toStringRV(vals[len(vals)-1]))
+	if err != nil {
+		return RV(time.Duration(0))
+	}
+	return RV(d)
+}
+
+// Ago renders a date as a coarse human-readable relative time, such as
+// "3 hours ago" or "in 2 days".
+func Ago(vals ...reflect.Value) reflect.Value {
+	if len(vals) == 0 {
+		return RV("")
+	}
+	t := parseTime(vals[len(vals)-1])
+	if t.IsZero() {
+		return RV("")
+	}
+	return RV(humanizeDuration(time.Since(t)))
+}
+
+func humanizeDuration(d time.Duration) string {
+	future := d < 0
+	if future {
+		d = -d
+	}
+
+	var value int
+	var unit string
+	switch {
+	case d < time.Minute:
+		value, unit = int(d/time.Second), "second"
+	case d < time.Hour:
+		value, unit = int(d/time.Minute), "minute"
+	case d < 24*time.Hour:
+		value, unit = int(d/time.Hour), "hour"
+	default:
+		value, unit = int(d/(24*time.Hour)), "day"
+	}
+	if value != 1 {
+		unit += "s"
+	}
+
+	if future {
+		return fmt.Sprintf("in %d %s", value, unit)
+	}
+	return fmt.Sprintf("%d %s ago", value, unit)
+}
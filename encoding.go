@@ -0,0 +1,65 @@
+package pipe
+
+import (
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"reflect"
+)
+
+// Base64 encodes a string using standard base64.
+func Base64(vals ...reflect.Value) reflect.Value {
+	if len(vals) == 0 {
+		return RV("")
+	}
+	return RV(base64.StdEncoding.EncodeToString([]byte(toStringRV(vals[len(vals)-1]))))
+}
+
+// Base64D decodes a standard base64 string, returning "" on invalid input.
+func Base64D(vals ...reflect.Value) reflect.Value {
+	if len(vals) == 0 {
+		return RV("")
+	}
+	b, err := base64.StdEncoding.DecodeString(toStringRV(vals[len(vals)-1]))
+	if err != nil {
+		return RV("")
+	}
+	return RV(string(b))
+}
+
+// UrlEncode escapes a string for use in a URL query component.
+func UrlEncode(vals ...reflect.Value) reflect.Value {
+	if len(vals) == 0 {
+		return RV("")
+	}
+	return RV(url.QueryEscape(toStringRV(vals[len(vals)-1])))
+}
+
+// Hex encodes a string as lowercase hexadecimal.
+func Hex(vals ...reflect.Value) reflect.Value {
+	if len(vals) == 0 {
+		return RV("")
+	}
+	return RV(hex.EncodeToString([]byte(toStringRV(vals[len(vals)-1]))))
+}
+
+// Md5 returns the hex-encoded MD5 digest of a string.
+func Md5(vals ...reflect.Value) reflect.Value {
+	if len(vals) == 0 {
+		return RV("")
+	}
+	sum := md5.Sum([]byte(toStringRV(vals[len(vals)-1])))
+	return RV(fmt.Sprintf("%x", sum))
+}
+
+// Sha256 returns the hex-encoded SHA-256 digest of a string.
+func Sha256(vals ...reflect.Value) reflect.Value {
+	if len(vals) == 0 {
+		return RV("")
+	}
+	sum := sha256.Sum256([]byte(toStringRV(vals[len(vals)-1])))
+	return RV(fmt.Sprintf("%x", sum))
+}
@@ -0,0 +1,73 @@
+package pipe
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"sync"
+)
+
+// ErrorMode controls how Add, Sub, Mul, Div, Nullish, and Ternary react to
+// a condition that would otherwise be silently papered over: a
+// non-numeric operand (Add/Sub/Mul/Div), division by zero (Div), or a
+// missing argument (Nullish/Ternary).
+type ErrorMode int
+
+const (
+	// ModeSilent, the default: arithmetic pipes fall back to 0 (or, for
+	// Nullish/Ternary, an invalid reflect.Value) instead of surfacing
+	// anything. A non-numeric operand (a bool included) makes the whole
+	// result 0; it is not counted as 0 while the other operands are
+	// still combined.
+	ModeSilent ErrorMode = iota
+	// ModeNaN returns math.NaN() instead of 0. Thousand (and Number/
+	// Currency) render a NaN or +/-Inf value as "NaN"/"∞"/"-∞" rather
+	// than blanking it out, so the failure stays visible downstream.
+	ModeNaN
+	// ModePanic panics with a string value describing the failed
+	// operation, for callers that would rather fail the render loudly
+	// than hand back a template with a silently wrong number in it.
+	ModePanic
+	// ModeReturnError returns a reflect.Value wrapping an error instead
+	// of a number, for callers whose template engine inspects pipe
+	// results before rendering them.
+	ModeReturnError
+)
+
+var (
+	errorModeMu sync.RWMutex
+	errorMode   = ModeSilent
+)
+
+// SetErrorMode sets how Add, Sub, Mul, Div, Nullish, and Ternary react to
+// a non-numeric operand, division by zero, or a missing argument (see
+// ErrorMode). It affects every template
+// using this package's registry, so set it once at startup.
+func SetErrorMode(m ErrorMode) {
+	errorModeMu.Lock()
+	defer errorModeMu.Unlock()
+	errorMode = m
+}
+
+func currentErrorMode() ErrorMode {
+	errorModeMu.RLock()
+	defer errorModeMu.RUnlock()
+	return errorMode
+}
+
+// arithError applies the current ErrorMode to a failed arithmetic
+// operation. silent is the whole pipe's result under ModeSilent (0 for
+// Add/Sub/Mul/Div, an invalid reflect.Value for Nullish/Ternary); the
+// other modes replace it only when a caller opts in via SetErrorMode.
+func arithError(pipeName, msg string, silent reflect.Value) reflect.Value {
+	switch currentErrorMode() {
+	case ModeNaN:
+		return RV(math.NaN())
+	case ModePanic:
+		panic(fmt.Sprintf("pipe: %s: %s", pipeName, msg))
+	case ModeReturnError:
+		return RV(fmt.Errorf("pipe: %s: %s", pipeName, msg))
+	default:
+		return silent
+	}
+}
@@ -0,0 +1,191 @@
+package pipe
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Locale describes how Number and Currency format a number: the
+// separators between groups of digits and between the integer and
+// fractional part, how many decimal places to keep, the currency symbol
+// and its placement, the digit-grouping schedule, and how negative
+// values are rendered.
+type Locale struct {
+	ThousandSep    string
+	DecimalSep     string
+	CurrencySymbol string
+	SymbolBefore   bool
+	Decimals       int
+
+	// Grouping is the digit-grouping schedule, sizes from the rightmost
+	// group outward. A single entry (the common case, e.g. {3}) repeats
+	// for every group; {3, 2} groups the last 3 digits together and
+	// every group to its left by 2 (the Indian lakh/crore system,
+	// e.g. 12,34,567). An empty Grouping behaves like {3}.
+	Grouping []int
+
+	// NegativeFormat is a fmt.Sprintf format with one %s verb, applied to
+	// the already-grouped, already-decimaled magnitude of a negative
+	// value. Defaults to "-%s" (e.g. "-1,234.56"); "(%s)" renders
+	// accounting-style parentheses instead.
+	NegativeFormat string
+}
+
+// LocaleUS: 1,234.56
+var LocaleUS = Locale{ThousandSep: ",", DecimalSep: ".", CurrencySymbol: "$", SymbolBefore: true, Decimals: 2, Grouping: []int{3}}
+
+// LocaleDE: 1.234,56€
+var LocaleDE = Locale{ThousandSep: ".", DecimalSep: ",", CurrencySymbol: "€", SymbolBefore: false, Decimals: 2, Grouping: []int{3}}
+
+// LocaleFR: 1 234,56€
+var LocaleFR = Locale{ThousandSep: " ", DecimalSep: ",", CurrencySymbol: "€", SymbolBefore: false, Decimals: 2, Grouping: []int{3}}
+
+// LocaleVN: 1.234₫ (Vietnamese dong is conventionally shown without decimals)
+var LocaleVN = Locale{ThousandSep: ".", DecimalSep: ",", CurrencySymbol: "₫", SymbolBefore: false, Decimals: 0, Grouping: []int{3}}
+
+// LocaleJP: ¥1,234 (yen has no subunit in everyday use)
+var LocaleJP = Locale{ThousandSep: ",", DecimalSep: ".", CurrencySymbol: "¥", SymbolBefore: true, Decimals: 0, Grouping: []int{3}}
+
+// LocaleINR: ₹12,34,567.89 (the Indian 3-2-2 lakh/crore grouping)
+var LocaleINR = Locale{ThousandSep: ",", DecimalSep: ".", CurrencySymbol: "₹", SymbolBefore: true, Decimals: 2, Grouping: []int{3, 2}}
+
+// locales maps the name a template author would pass to number/currency
+// to the predefined Locale it selects.
+var locales = map[string]Locale{
+	"us":  LocaleUS,
+	"de":  LocaleDE,
+	"fr":  LocaleFR,
+	"vn":  LocaleVN,
+	"jp":  LocaleJP,
+	"inr": LocaleINR,
+}
+
+// resolveLocale looks for an optional locale name in vals[0]: if present
+// and recognized, it returns that Locale and the remaining arguments;
+// otherwise it returns LocaleUS and vals unchanged.
+func resolveLocale(vals []reflect.Value) (Locale, []reflect.Value) {
+	if len(vals) > 1 && vals[0].Kind() == reflect.String {
+		if loc, ok := locales[strings.ToLower(vals[0].String())]; ok {
+			return loc, vals[1:]
+		}
+	}
+	return LocaleUS, vals
+}
+
+// groupDigits inserts sep into digits according to grouping, working from
+// the rightmost digit outward. See Locale.Grouping for the schedule
+// semantics.
+func groupDigits(digits string, sep string, grouping []int) string {
+	if len(grouping) == 0 {
+		grouping = []int{3}
+	}
+	var groups []string
+	i := len(digits)
+	gi := 0
+	for i > 0 {
+		size := grouping[gi]
+		if size <= 0 {
+			size = 3
+		}
+		if gi < len(grouping)-1 {
+			gi++
+		}
+		if size > i {
+			size = i
+		}
+		start := i - size
+		groups = append([]string{digits[start:i]}, groups...)
+		i = start
+	}
+	return strings.Join(groups, sep)
+}
+
+// formatLocaleNumber renders num under loc: grouped integer part, decimal
+// part, and sign applied via loc.NegativeFormat rather than a bare "-"
+// prepended before grouping (which would otherwise land the thousand
+// separator between the sign and the first digit).
+func formatLocaleNumber(loc Locale, num float64) string {
+	// A NaN or +/-Inf value (e.g. from Div under ModeNaN) renders as
+	// itself rather than running the grouping/decimal logic on it, same
+	// as Thousand.
+	switch {
+	case math.IsNaN(num):
+		return "NaN"
+	case math.IsInf(num, 1):
+		return "∞"
+	case math.IsInf(num, -1):
+		return "-∞"
+	}
+
+	neg := math.Signbit(num) && num != 0
+	if neg {
+		num = -num
+	}
+
+	num = math.Round(num*pow10(loc.Decimals)) / pow10(loc.Decimals)
+
+	s := strconv.FormatFloat(num, 'f', loc.Decimals, 64)
+	parts := strings.SplitN(s, ".", 2)
+	intPart := parts[0]
+	fracPart := ""
+	if len(parts) > 1 {
+		fracPart = parts[1]
+	}
+
+	result := groupDigits(intPart, loc.ThousandSep, loc.Grouping)
+	if loc.Decimals > 0 {
+		result += loc.DecimalSep + fracPart
+	}
+
+	if neg {
+		negFmt := loc.NegativeFormat
+		if negFmt == "" {
+			negFmt = "-%s"
+		}
+		result = fmt.Sprintf(negFmt, result)
+	}
+
+	return result
+}
+
+// Number formats vals[last] under an optional locale named in vals[0]
+// ("us", "de", "fr", "vn", "jp", or "inr"; defaults to "us"), applying
+// that locale's separators, decimal places, and digit-grouping schedule.
+func Number(vals ...reflect.Value) reflect.Value {
+	if len(vals) == 0 {
+		return RV("")
+	}
+	loc, rest := resolveLocale(vals)
+	if len(rest) == 0 {
+		return RV("")
+	}
+	num, ok := tryFloat(rest[len(rest)-1])
+	if !ok {
+		return RV("")
+	}
+	return RV(formatLocaleNumber(loc, num))
+}
+
+// Currency formats vals[last] like Number, then adds the locale's
+// currency symbol, before or after according to loc.SymbolBefore.
+func Currency(vals ...reflect.Value) reflect.Value {
+	if len(vals) == 0 {
+		return RV("")
+	}
+	loc, rest := resolveLocale(vals)
+	if len(rest) == 0 {
+		return RV("")
+	}
+	num, ok := tryFloat(rest[len(rest)-1])
+	if !ok {
+		return RV("")
+	}
+	formatted := formatLocaleNumber(loc, num)
+	if loc.SymbolBefore {
+		return RV(loc.CurrencySymbol + formatted)
+	}
+	return RV(formatted + loc.CurrencySymbol)
+}
@@ -0,0 +1,31 @@
+package pipe
+
+import "testing"
+
+// TestCompileLocaleBareCall guards against Compile rejecting the
+// locale-name-first bare-call form Number/Currency's doc comments
+// describe, which previously failed with a PreprocessError (fixed
+// alongside the bare-action pass-through in chunk0-5).
+func TestCompileLocaleBareCall(t *testing.T) {
+	cases := []struct {
+		tmpl string
+		data map[string]any
+		want string
+	}{
+		{`{{ currency "de" $x }}`, map[string]any{"x": 1234567.89}, "1.234.567,89€"},
+		{`{{ number "us" -1234.5 }}`, nil, "-1,234.50"},
+	}
+	for _, c := range cases {
+		tpl, err := Compile("locale-bare-"+c.tmpl, c.tmpl)
+		if err != nil {
+			t.Fatalf("%s: Compile: %v", c.tmpl, err)
+		}
+		out, err := tpl.ExecuteString(map[string]any{"Vars": c.data})
+		if err != nil {
+			t.Fatalf("%s: ExecuteString: %v", c.tmpl, err)
+		}
+		if out != c.want {
+			t.Fatalf("%s: got %q, want %q", c.tmpl, out, c.want)
+		}
+	}
+}
@@ -8,10 +8,17 @@ func Add(vals ...reflect.Value) reflect.Value {
 		return RV(float64(0))
 	}
 	// left is last (accumulator)
-	left := toFloatRV(vals[ln-1])
+	left, ok := tryFloat(vals[ln-1])
+	if !ok {
+		return arithError("add", "operand is not a number", RV(float64(0)))
+	}
 	// add all previous
 	for i := 0; i < ln-1; i++ {
-		left += toFloatRV(vals[i])
+		v, ok := tryFloat(vals[i])
+		if !ok {
+			return arithError("add", "operand is not a number", RV(float64(0)))
+		}
+		left += v
 	}
 	return RV(left)
 }
@@ -22,10 +29,17 @@ func Sub(vals ...reflect.Value) reflect.Value {
 		return RV(float64(0))
 	}
 	// left is last (accumulator)
-	left := toFloatRV(vals[ln-1])
+	left, ok := tryFloat(vals[ln-1])
+	if !ok {
+		return arithError("sub", "operand is not a number", RV(float64(0)))
+	}
 	// subtract all previous in order
 	for i := 0; i < ln-1; i++ {
-		left -= toFloatRV(vals[i])
+		v, ok := tryFloat(vals[i])
+		if !ok {
+			return arithError("sub", "operand is not a number", RV(float64(0)))
+		}
+		left -= v
 	}
 	return RV(left)
 }
@@ -35,9 +49,16 @@ func Mul(vals ...reflect.Value) reflect.Value {
 	if ln == 0 {
 		return RV(float64(0))
 	}
-	left := toFloatRV(vals[ln-1])
+	left, ok := tryFloat(vals[ln-1])
+	if !ok {
+		return arithError("mul", "operand is not a number", RV(float64(0)))
+	}
 	for i := 0; i < ln-1; i++ {
-		left *= toFloatRV(vals[i])
+		v, ok := tryFloat(vals[i])
+		if !ok {
+			return arithError("mul", "operand is not a number", RV(float64(0)))
+		}
+		left *= v
 	}
 	return RV(left)
 }
@@ -47,26 +68,233 @@ func Div(vals ...reflect.Value) reflect.Value {
 	if ln == 0 {
 		return RV(float64(0))
 	}
-	left := toFloatRV(vals[ln-1])
+	left, ok := tryFloat(vals[ln-1])
+	if !ok {
+		return arithError("div", "operand is not a number", RV(float64(0)))
+	}
 	for i := 0; i < ln-1; i++ {
-		d := toFloatRV(vals[i])
+		d, ok := tryFloat(vals[i])
+		if !ok {
+			return arithError("div", "operand is not a number", RV(float64(0)))
+		}
 		if d == 0 {
-			// tránh panic / inf: trả 0 (hoặc bạn có thể chọn trả math.Inf)
-			return RV(float64(0))
+			return arithError("div", "division by zero", RV(float64(0)))
+		}
+		left /= d
+	}
+	return RV(left)
+}
+
+// DivMod divides the left operand (vals[len-1]) by the right operand
+// (vals[0]), truncating both to int64 first, and returns []any{quotient,
+// remainder}. Division by zero is handled like Div, via the current
+// ErrorMode.
+func DivMod(vals ...reflect.Value) reflect.Value {
+	if len(vals) < 2 {
+		return RV([]any{int64(0), int64(0)})
+	}
+	b := toIntRV(vals[0])
+	if b == 0 {
+		return arithError("divmod", "division by zero", RV([]any{int64(0), int64(0)}))
+	}
+	a := toIntRV(vals[len(vals)-1])
+	return RV([]any{a / b, a % b})
+}
+
+// SafeDiv divides like Div (vals[1..len-2] are divisors applied in order
+// to the dividend vals[len-1]), but instead of honoring ErrorMode it
+// always falls back to an explicit default value, vals[0], on a
+// non-numeric operand or division by zero. Use this when a template
+// needs a specific fallback (e.g. "N/A") rather than whatever the
+// package-wide ErrorMode produces.
+func SafeDiv(vals ...reflect.Value) reflect.Value {
+	if len(vals) < 2 {
+		return RV(float64(0))
+	}
+	def := vals[0]
+	rest := vals[1:]
+	ln := len(rest)
+	left, ok := tryFloat(rest[ln-1])
+	if !ok {
+		return def
+	}
+	for i := 0; i < ln-1; i++ {
+		d, ok := tryFloat(rest[i])
+		if !ok || d == 0 {
+			return def
 		}
 		left /= d
 	}
 	return RV(left)
 }
 
+// Mod returns the left operand (vals[len-1]) modulo the right operand
+// (vals[0]), truncating either toward int64 first.
+func Mod(vals ...reflect.Value) reflect.Value {
+	if len(vals) < 2 {
+		return RV(int64(0))
+	}
+	b := toIntRV(vals[0])
+	if b == 0 {
+		return RV(int64(0))
+	}
+	return RV(toIntRV(vals[len(vals)-1]) % b)
+}
+
+// Band is the bitwise AND of the left and right operands.
+func Band(vals ...reflect.Value) reflect.Value {
+	if len(vals) < 2 {
+		return RV(int64(0))
+	}
+	return RV(toIntRV(vals[len(vals)-1]) & toIntRV(vals[0]))
+}
+
+// Bor is the bitwise OR of the left and right operands.
+func Bor(vals ...reflect.Value) reflect.Value {
+	if len(vals) < 2 {
+		return RV(int64(0))
+	}
+	return RV(toIntRV(vals[len(vals)-1]) | toIntRV(vals[0]))
+}
+
+// Xor is the bitwise XOR of the left and right operands.
+func Xor(vals ...reflect.Value) reflect.Value {
+	if len(vals) < 2 {
+		return RV(int64(0))
+	}
+	return RV(toIntRV(vals[len(vals)-1]) ^ toIntRV(vals[0]))
+}
+
+// Shl shifts the left operand left by the right operand's bit count.
+func Shl(vals ...reflect.Value) reflect.Value {
+	if len(vals) < 2 {
+		return RV(int64(0))
+	}
+	shift := toIntRV(vals[0])
+	if shift < 0 {
+		return RV(int64(0))
+	}
+	return RV(toIntRV(vals[len(vals)-1]) << shift)
+}
+
+// Shr shifts the left operand right by the right operand's bit count.
+func Shr(vals ...reflect.Value) reflect.Value {
+	if len(vals) < 2 {
+		return RV(int64(0))
+	}
+	shift := toIntRV(vals[0])
+	if shift < 0 {
+		return RV(int64(0))
+	}
+	return RV(toIntRV(vals[len(vals)-1]) >> shift)
+}
+
+// Nullish follows the same "left is last" convention as Add/Sub/etc: the
+// piped-in value (vals[len-1]) is the primary operand, vals[0] is the
+// fallback used when it is zero or invalid.
 func Nullish(vals ...reflect.Value) reflect.Value {
-	if vals[0].IsZero() || !vals[0].IsValid() {
-		return vals[1]
+	if len(vals) < 2 {
+		return arithError("nullish", "requires a primary value and a fallback", reflect.Value{})
+	}
+	primary := vals[len(vals)-1]
+	fallback := vals[0]
+	if !primary.IsValid() || primary.IsZero() {
+		return fallback
+	}
+	return primary
+}
+
+// Eq reports whether two values are equal, comparing numerically when
+// both sides coerce to a number and as strings otherwise.
+func Eq(vals ...reflect.Value) reflect.Value {
+	if len(vals) < 2 {
+		return RV(false)
+	}
+	return RV(valuesEqual(vals[len(vals)-1], vals[0]))
+}
+
+// Ne is the negation of Eq.
+func Ne(vals ...reflect.Value) reflect.Value {
+	if len(vals) < 2 {
+		return RV(true)
+	}
+	return RV(!valuesEqual(vals[len(vals)-1], vals[0]))
+}
+
+// Gt reports whether the left operand (vals[len-1]) is greater than the
+// right operand (vals[0]).
+func Gt(vals ...reflect.Value) reflect.Value {
+	if len(vals) < 2 {
+		return RV(false)
+	}
+	return RV(toFloatRV(vals[len(vals)-1]) > toFloatRV(vals[0]))
+}
+
+// Lt reports whether the left operand is less than the right operand.
+func Lt(vals ...reflect.Value) reflect.Value {
+	if len(vals) < 2 {
+		return RV(false)
 	}
-	return vals[0]
+	return RV(toFloatRV(vals[len(vals)-1]) < toFloatRV(vals[0]))
+}
+
+// Gte reports whether the left operand is greater than or equal to the
+// right operand.
+func Gte(vals ...reflect.Value) reflect.Value {
+	if len(vals) < 2 {
+		return RV(false)
+	}
+	return RV(toFloatRV(vals[len(vals)-1]) >= toFloatRV(vals[0]))
+}
+
+// Lte reports whether the left operand is less than or equal to the
+// right operand.
+func Lte(vals ...reflect.Value) reflect.Value {
+	if len(vals) < 2 {
+		return RV(false)
+	}
+	return RV(toFloatRV(vals[len(vals)-1]) <= toFloatRV(vals[0]))
+}
+
+// And is a boolean AND of the left and right operands.
+func And(vals ...reflect.Value) reflect.Value {
+	if len(vals) < 2 {
+		return RV(false)
+	}
+	return RV(toBoolRV(vals[len(vals)-1]) && toBoolRV(vals[0]))
+}
+
+// Or is a boolean OR of the left and right operands.
+func Or(vals ...reflect.Value) reflect.Value {
+	if len(vals) < 2 {
+		return RV(false)
+	}
+	return RV(toBoolRV(vals[len(vals)-1]) || toBoolRV(vals[0]))
+}
+
+// Not is a boolean negation of its single operand.
+func Not(vals ...reflect.Value) reflect.Value {
+	if len(vals) < 1 {
+		return RV(true)
+	}
+	return RV(!toBoolRV(vals[len(vals)-1]))
+}
+
+// valuesEqual compares a and b numerically if both coerce to a number,
+// falling back to a string comparison otherwise.
+func valuesEqual(a, b reflect.Value) bool {
+	af, aok := tryFloat(a)
+	bf, bok := tryFloat(b)
+	if aok && bok {
+		return af == bf
+	}
+	return toStringRV(a) == toStringRV(b)
 }
 
 func Ternary(vals ...reflect.Value) reflect.Value {
+	if len(vals) < 3 {
+		return arithError("tern", "requires a condition, a true branch, and a false branch", reflect.Value{})
+	}
 	cond := vals[0]
 	var ok bool
 
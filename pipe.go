@@ -10,29 +10,122 @@ import (
 	"strings"
 )
 
-// New creates a completely new template.FuncMap by copying from the global `functions` map.
-// Use this when you want to run multiple independent template engines,
-// or add new pipes without affecting the original map.
-// Each call to New() returns a separate, independent map.
-func New() template.FuncMap { // copy map / clone map
-	funcs := template.FuncMap{}
-	for k, v := range functions {
-		funcs[k] = v
-	}
-	return funcs
+// New creates a completely new template.FuncMap, snapshotting the global
+// registry under its read lock. Use this when you want to run multiple
+// independent template engines, or add new pipes (via the returned map,
+// not RegisterPipe) without affecting the global registry. Each call to
+// New() returns a separate, independent map.
+func New() template.FuncMap {
+	return Snapshot()
+}
+
+// Functions returns a snapshot of the global pipe registry. Use this when
+// you only need a single template engine built from whatever is
+// registered right now; it does not track later RegisterPipe/Unregister
+// calls, so re-call it if the registry changes.
+func Functions() template.FuncMap {
+	return Snapshot()
 }
 
-// Functions returns the global `functions` map.
-// Use this when you only need a single template engine, with static functions,
-// and you won't be adding new pipes at runtime. This map is shared across all usages.
-func Functions() template.FuncMap { // map global
-	return functions
+// lookupPipe resolves a registered pipe by name for the handful of
+// higher-order pipes (map/filter/reduce) that take a pipe name as an
+// argument instead of being called by the template engine directly.
+func lookupPipe(name string) (func(...reflect.Value) reflect.Value, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+	fn, ok := functions[name].(func(...reflect.Value) reflect.Value)
+	return fn, ok
 }
 
-var functions = template.FuncMap{
-	"json":     Json,
-	"thousand": Thousand,
-	"dollar":   Dollar,
+// callPipe invokes a pipe resolved via lookupPipe.
+func callPipe(fn func(...reflect.Value) reflect.Value, args []reflect.Value) reflect.Value {
+	return fn(args...)
+}
+
+// functions is populated in init() rather than as a map literal: a couple
+// of entries (map/filter/reduce) call back into lookupPipe, which reads
+// this same var, and a literal would make that a compile-time
+// initialization cycle. It lives here but is declared (and guarded by mu)
+// in registry.go alongside the rest of the dynamic registry.
+
+func init() {
+	functions["json"] = Json
+	functions["thousand"] = Thousand
+	functions["dollar"] = Dollar
+	functions["number"] = Number
+	functions["currency"] = Currency
+
+	// arithmetic/logic, used by the expression preprocessor
+	functions["add"] = Add
+	functions["sub"] = Sub
+	functions["mul"] = Mul
+	functions["div"] = Div
+	functions["nullish"] = Nullish
+	functions["tern"] = Ternary
+	functions["eq"] = Eq
+	functions["ne"] = Ne
+	functions["gt"] = Gt
+	functions["lt"] = Lt
+	functions["gte"] = Gte
+	functions["lte"] = Lte
+	functions["and"] = And
+	functions["or"] = Or
+	functions["not"] = Not
+	functions["mod"] = Mod
+	functions["divmod"] = DivMod
+	functions["safeDiv"] = SafeDiv
+	functions["band"] = Band
+	functions["bor"] = Bor
+	functions["xor"] = Xor
+	functions["shl"] = Shl
+	functions["shr"] = Shr
+
+	// strings
+	functions["upper"] = Upper
+	functions["lower"] = Lower
+	functions["title"] = Title
+	functions["trim"] = Trim
+	functions["trimPrefix"] = TrimPrefix
+	functions["trimSuffix"] = TrimSuffix
+	functions["replace"] = Replace
+	functions["split"] = Split
+	functions["join"] = Join
+	functions["contains"] = Contains
+	functions["hasPrefix"] = HasPrefix
+	functions["hasSuffix"] = HasSuffix
+	functions["repeat"] = Repeat
+	functions["truncate"] = Truncate
+	functions["slug"] = Slug
+	functions["padLeft"] = PadLeft
+	functions["padRight"] = PadRight
+
+	// collections
+	functions["first"] = First
+	functions["last"] = Last
+	functions["len"] = Len
+	functions["reverse"] = Reverse
+	functions["sort"] = Sort
+	functions["uniq"] = Uniq
+	functions["map"] = Map
+	functions["filter"] = Filter
+	functions["reduce"] = Reduce
+	functions["range"] = Range
+	functions["index"] = Index
+
+	// dates
+	functions["now"] = Now
+	functions["date"] = Date
+	functions["dateFormat"] = DateFormat
+	functions["duration"] = Duration
+	functions["ago"] = Ago
+
+	// encoding
+	functions["base64"] = Base64
+	functions["base64d"] = Base64D
+	functions["urlEncode"] = UrlEncode
+	functions["hex"] = Hex
+	functions["md5"] = Md5
+	functions["sha256"] = Sha256
 }
 
 // Thousand formats a number with thousand separators and optional decimal places.
@@ -89,6 +182,17 @@ func Thousand(vals ...reflect.Value) reflect.Value {
 		return reflect.ValueOf("")
 	}
 
+	// A NaN or +/-Inf value (e.g. from Div under ModeNaN) renders as
+	// itself rather than blanking out, so the failure stays visible.
+	switch {
+	case math.IsNaN(num):
+		return reflect.ValueOf("NaN")
+	case math.IsInf(num, 1):
+		return reflect.ValueOf("∞")
+	case math.IsInf(num, -1):
+		return reflect.ValueOf("-∞")
+	}
+
 	// Round the number
 	num = math.Round(num*pow10(decimal)) / pow10(decimal)
 
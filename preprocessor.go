@@ -3,6 +3,7 @@ package pipe
 import (
 	"fmt"
 	"regexp"
+	"strconv"
 	"strings"
 	"unicode"
 )
@@ -15,25 +16,23 @@ const (
 	Op    TokenType = "op"
 	Paren TokenType = "paren"
 	Tern  TokenType = "tern"
+	Str   TokenType = "str"
+	Call  TokenType = "call"
 )
 
+// callArgSep joins a Call token's function name and arguments into a
+// single Token.value (tokens carry one string). It's a control character
+// that can't appear in source expressions, so splitting is unambiguous.
+const callArgSep = "\x00"
+
 type Token struct {
 	typ   TokenType
 	value string
 }
 
-var opMap = map[string]string{
-	"+": "add", "-": "sub", "*": "mul", "/": "div",
-	"==": "eq", "!=": "ne", ">": "gt", "<": "lt", ">=": "gte", "<=": "lte",
-	"&&": "and", "||": "or", "!": "not", "??": "nullish",
-	"?": "tern",
-}
-
-var precedence = map[string]int{
-	"!": 6, "*": 5, "/": 5, "+": 4, "-": 4,
-	">": 3, "<": 3, ">=": 3, "<=": 3, "==": 3, "!=": 3,
-	"&&": 2, "||": 1, "??": 0, // ternary handled separately
-}
+// Operator symbols, their precedence/associativity, and the pipe each one
+// rewrites to now live in the dynamic registry (registry.go), seeded with
+// the built-ins there and extendable at runtime via RegisterOperator.
 
 // tokenize: supports numbers, identifiers (with dots), strings (not quoted here), parens and operators
 func tokenize(expr string) []Token {
@@ -57,27 +56,111 @@ func tokenize(expr string) []Token {
 			continue
 		}
 
-		// two-char ops
-		if i+1 < n {
-			two := expr[i : i+2]
-			if two == "==" || two == "!=" || two == ">=" || two == "<=" ||
-				two == "&&" || two == "||" || two == "??" {
-				tokens = append(tokens, Token{Op, two})
-				i += 2
+		// unary minus: a '-' immediately before a digit, where the
+		// previous token (if any) is an operator or an opening paren,
+		// is the sign of a negative literal rather than subtraction -
+		// in "2 - -3" the second '-' belongs to the literal -3.
+		if ch == '-' && i+1 < n && unicode.IsDigit(rune(expr[i+1])) && atOperandStart(tokens) {
+			start := i
+			i++ // consume '-'
+			hasDot := false
+			for i < n && (unicode.IsDigit(rune(expr[i])) || (!hasDot && expr[i] == '.')) {
+				if expr[i] == '.' {
+					hasDot = true
+				}
+				i++
+			}
+			tokens = append(tokens, Token{Num, expr[start:i]})
+			continue
+		}
+
+		// "|" is ambiguous: it's both the bitwise-or operator and the
+		// template engine's own pipe syntax ("{{ $x | upper }}"). Only
+		// treat it as bor when the right-hand side isn't a bare
+		// registered pipe name; "| dollar", "| upper | lower", etc. are
+		// left untouched so they reach text/template as a genuine pipe.
+		if expr[i] == '|' && (i+1 >= n || expr[i+1] != '|') {
+			if _, ok := peekPipeName(expr, i); ok {
+				end := pipeChainEnd(expr, i)
+				rest := expr[i:end]
+				if len(tokens) > 0 {
+					tokens[len(tokens)-1].value += " " + rest
+				} else {
+					tokens = append(tokens, Token{Var, rest})
+				}
+				i = end
 				continue
 			}
 		}
 
-		// single char ops (include ? and :)
-		if strings.ContainsRune("+-*/><!:?", rune(ch)) {
+		// operator: longest match against the registered operator symbols,
+		// so RegisterOperator'd symbols of any length are recognized too
+		if sym, ok := matchOperatorSymbol(expr, i); ok {
+			tokens = append(tokens, Token{Op, sym})
+			i += len(sym)
+			continue
+		}
+
+		// ternary syntax markers are structural, not registered operators
+		if ch == '?' || ch == ':' {
 			tokens = append(tokens, Token{Op, string(ch)})
 			i++
 			continue
 		}
 
-		// number (integer or float)
+		// string literal, single- or double-quoted, with \n \t \" \\ escapes
+		if ch == '"' || ch == '\'' {
+			quote := ch
+			var sb strings.Builder
+			i++ // skip opening quote
+			for i < n && expr[i] != quote {
+				if expr[i] == '\\' && i+1 < n {
+					switch expr[i+1] {
+					case 'n':
+						sb.WriteByte('\n')
+					case 't':
+						sb.WriteByte('\t')
+					case '"':
+						sb.WriteByte('"')
+					case '\'':
+						sb.WriteByte('\'')
+					case '\\':
+						sb.WriteByte('\\')
+					default:
+						sb.WriteByte(expr[i+1])
+					}
+					i += 2
+					continue
+				}
+				sb.WriteByte(expr[i])
+				i++
+			}
+			if i < n {
+				i++ // skip closing quote
+			}
+			tokens = append(tokens, Token{Str, sb.String()})
+			continue
+		}
+
+		// number (integer, float, or 0x.../0b... literal)
 		if unicode.IsDigit(rune(ch)) {
 			start := i
+			if ch == '0' && i+1 < n && (expr[i+1] == 'x' || expr[i+1] == 'X') {
+				i += 2
+				for i < n && isHexDigit(expr[i]) {
+					i++
+				}
+				tokens = append(tokens, Token{Num, expr[start:i]})
+				continue
+			}
+			if ch == '0' && i+1 < n && (expr[i+1] == 'b' || expr[i+1] == 'B') {
+				i += 2
+				for i < n && (expr[i] == '0' || expr[i] == '1') {
+					i++
+				}
+				tokens = append(tokens, Token{Num, expr[start:i]})
+				continue
+			}
 			hasDot := false
 			for i < n && (unicode.IsDigit(rune(expr[i])) || (!hasDot && expr[i] == '.')) {
 				if expr[i] == '.' {
@@ -95,7 +178,32 @@ func tokenize(expr string) []Token {
 			for i < n && (unicode.IsLetter(rune(expr[i])) || unicode.IsDigit(rune(expr[i])) || expr[i] == '_' || expr[i] == '.') {
 				i++
 			}
-			tokens = append(tokens, Token{Var, expr[start:i]})
+			name := expr[start:i]
+
+			// function-call syntax: identifier immediately followed by '('
+			j := i
+			for j < n && unicode.IsSpace(rune(expr[j])) {
+				j++
+			}
+			if j < n && expr[j] == '(' {
+				if close := matchingParen(expr, j); close != -1 {
+					inner := strings.TrimSpace(expr[j+1 : close])
+					var args []string
+					if inner != "" {
+						args = splitTopLevel(inner, ',')
+					}
+					parts := make([]string, 0, len(args)+1)
+					parts = append(parts, name)
+					for _, a := range args {
+						parts = append(parts, strings.TrimSpace(a))
+					}
+					tokens = append(tokens, Token{Call, strings.Join(parts, callArgSep)})
+					i = close + 1
+					continue
+				}
+			}
+
+			tokens = append(tokens, Token{Var, name})
 			continue
 		}
 
@@ -107,23 +215,36 @@ func tokenize(expr string) []Token {
 	return tokens
 }
 
-// parseTernary: find "cond ? a : b" occurrences and collapse to single Tern token.
-// We build Tern token value as: tern(<cond_expr>,<true_expr>,<false_expr>) without converting those sub-expr to pipeline yet.
+// parseTernary is the lenient entry point used by the existing
+// preprocessing path: on a malformed ternary (no matching ':') it falls
+// back to returning the tokens largely as-is instead of failing. Compile
+// uses parseTernaryE directly so it can report the error instead.
 func parseTernary(tokens []Token) []Token {
+	out, _ := parseTernaryE(tokens)
+	return out
+}
+
+// parseTernaryE finds a "cond ? a : b" occurrence and collapses it to a
+// single Tern token, built as tern(<cond_expr>,<true_expr>,<false_expr>)
+// without converting those sub-expressions to a pipeline yet. It returns
+// an error (alongside its best-effort fallback tokens) when a '?' has no
+// matching ':'.
+func parseTernaryE(tokens []Token) ([]Token, error) {
 	var out []Token
 	i := 0
 	for i < len(tokens) {
 		// find '?'
 		if tokens[i].typ == Op && tokens[i].value == "?" {
-			// cond is last item in out
+			// cond is everything accumulated so far, which may itself be a
+			// multi-token comparison/logical expression (e.g. $a == $b)
 			if len(out) == 0 {
 				// malformed, just append and continue
 				out = append(out, tokens[i])
 				i++
 				continue
 			}
-			cond := out[len(out)-1]
-			out = out[:len(out)-1]
+			cond := out
+			out = nil
 
 			// find matching ':'
 			depth := 0
@@ -139,19 +260,20 @@ func parseTernary(tokens []Token) []Token {
 				}
 			}
 			if j >= len(tokens) {
-				// malformed: no matching colon -> append remaining and break
-				out = append(out, cond)
+				// malformed: no matching colon -> append remaining and return an error
+				out = append(out, cond...)
 				out = append(out, tokens[i:]...)
-				break
+				return out, fmt.Errorf("ternary '?' has no matching ':'")
 			}
 			trueBranch := tokens[i+1 : j]
 			// falseBranch is remainder after j
 			falseBranch := tokens[j+1:]
 
 			// create Tern token value by serializing sub-tokens to string (we'll re-tokenize these when needed)
+			cb := tokensToString(cond)
 			tb := tokensToString(trueBranch)
 			fb := tokensToString(falseBranch)
-			out = append(out, Token{Tern, fmt.Sprintf("tern(%s,%s,%s)", cond.value, tb, fb)})
+			out = append(out, Token{Tern, fmt.Sprintf("tern(%s,%s,%s)", cb, tb, fb)})
 			// Done with whole expression; break the loop because falseBranch consumed rest
 			break
 		}
@@ -159,7 +281,7 @@ func parseTernary(tokens []Token) []Token {
 		out = append(out, tokens[i])
 		i++
 	}
-	return out
+	return out, nil
 }
 
 func tokensToString(ts []Token) string {
@@ -168,11 +290,125 @@ func tokensToString(ts []Token) string {
 		if i > 0 {
 			b.WriteByte(' ')
 		}
-		b.WriteString(t.value)
+		switch t.typ {
+		case Str:
+			b.WriteString(strconv.Quote(t.value))
+		case Call:
+			parts := strings.Split(t.value, callArgSep)
+			b.WriteString(parts[0] + "(" + strings.Join(parts[1:], ", ") + ")")
+		default:
+			b.WriteString(t.value)
+		}
 	}
 	return b.String()
 }
 
+// peekPipeName looks just past the '|' at position i in expr for a bare
+// identifier (no '$' or '.', as "Vars.xxx" variable references have)
+// that names a registered pipe, e.g. the "dollar" in "... | dollar". It
+// returns false for a number, a variable reference, or an unregistered
+// name, so the tokenizer only takes the genuine-template-pipe path in
+// that one case and falls back to the bor operator otherwise.
+func peekPipeName(expr string, i int) (string, bool) {
+	n := len(expr)
+	j := i + 1 // skip '|'
+	for j < n && unicode.IsSpace(rune(expr[j])) {
+		j++
+	}
+	start := j
+	for j < n && (unicode.IsLetter(rune(expr[j])) || unicode.IsDigit(rune(expr[j])) || expr[j] == '_') {
+		j++
+	}
+	if j == start {
+		return "", false
+	}
+	name := expr[start:j]
+	if !pipeRegistered(name) {
+		return "", false
+	}
+	return name, true
+}
+
+// pipeChainEnd returns the exclusive end index of the genuine-template-pipe
+// chain starting at the '|' at position i in expr, i.e. everything that
+// should be passed through untouched (further "| name" links, their
+// string-literal args, ...) up to whatever closes the current scope: a
+// top-level ')' belonging to an enclosing call/group, or a top-level '?'
+// / ':' belonging to an enclosing ternary. Quoted string args are
+// skipped over whole so a '?' or paren inside one isn't mistaken for a
+// boundary.
+func pipeChainEnd(expr string, i int) int {
+	n := len(expr)
+	depth := 0
+	j := i
+	for j < n {
+		switch ch := expr[j]; ch {
+		case '"', '\'':
+			quote := ch
+			j++
+			for j < n && expr[j] != quote {
+				if expr[j] == '\\' && j+1 < n {
+					j += 2
+					continue
+				}
+				j++
+			}
+			if j < n {
+				j++
+			}
+			continue
+		case '(':
+			depth++
+		case ')':
+			if depth == 0 {
+				return j
+			}
+			depth--
+		case '?', ':':
+			if depth == 0 {
+				return j
+			}
+		}
+		j++
+	}
+	return n
+}
+
+// isHexDigit reports whether b is a valid hex digit character.
+func isHexDigit(b byte) bool {
+	return (b >= '0' && b <= '9') || (b >= 'a' && b <= 'f') || (b >= 'A' && b <= 'F')
+}
+
+// atOperandStart reports whether the next token in tokenize is in operand
+// position - the start of the expression, or right after an operator or
+// an opening paren - which is what distinguishes a leading '-' as a
+// negative literal's sign rather than the subtraction operator.
+func atOperandStart(tokens []Token) bool {
+	if len(tokens) == 0 {
+		return true
+	}
+	last := tokens[len(tokens)-1]
+	return last.typ == Op || (last.typ == Paren && last.value == "(")
+}
+
+// matchingParen returns the index in s of the ')' matching the '(' at
+// index open, or -1 if unbalanced.
+func matchingParen(s string, open int) int {
+	depth := 0
+	for i := open; i < len(s); i++ {
+		switch s[i] {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
 // toRPN (Shunting-yard). Treat Tern as an operand token (it is already a single token).
 func toRPN(tokens []Token) []Token {
 	var out []Token
@@ -180,7 +416,7 @@ func toRPN(tokens []Token) []Token {
 
 	for _, tok := range tokens {
 		switch tok.typ {
-		case Var, Num, Tern:
+		case Var, Num, Tern, Str, Call:
 			out = append(out, tok)
 		case Op:
 			// handle '(' / ')'
@@ -198,10 +434,16 @@ func toRPN(tokens []Token) []Token {
 				}
 				continue
 			}
-			for len(stack) > 0 && stack[len(stack)-1].typ == Op {
+			curDef, curOk := lookupOperator(tok.value)
+			for len(stack) > 0 && stack[len(stack)-1].typ == Op && curOk {
 				top := stack[len(stack)-1]
-				// if top has higher or equal precedence, pop it
-				if precedence[top.value] >= precedence[tok.value] {
+				topDef, topOk := lookupOperator(top.value)
+				if !topOk {
+					break
+				}
+				// pop top if it binds tighter, or equally tight and the
+				// current operator is left-associative
+				if topDef.prec > curDef.prec || (topDef.prec == curDef.prec && curDef.assoc == LeftAssoc) {
 					out = append(out, top)
 					stack = stack[:len(stack)-1]
 					continue
@@ -231,12 +473,24 @@ func toRPN(tokens []Token) []Token {
 	return out
 }
 
-// rpnToPipeline: safe, checks stack underflow, supports Tern token.
+// rpnToPipeline is the lenient entry point used by the existing
+// preprocessing path: on any error it silently falls back to the original
+// (re-serialized) RPN instead of failing the whole template. Compile uses
+// rpnToPipelineE directly so it can report the error instead.
 func rpnToPipeline(rpn []Token) string {
-	// fallback: nếu có gì sai -> trả lại RPN gốc
-	original := tokensToString(rpn)
+	s, err := rpnToPipelineE(rpn)
+	if err != nil {
+		return tokensToString(rpn)
+	}
+	return s
+}
 
+// rpnToPipelineE does the actual RPN-to-pipeline conversion, checking for
+// stack underflow, unknown operators, and malformed Tern tokens, and
+// returning a descriptive error instead of silently falling back.
+func rpnToPipelineE(rpn []Token) (string, error) {
 	var stack []string
+	hadOp := false
 
 	for _, tok := range rpn {
 		switch tok.typ {
@@ -244,17 +498,42 @@ func rpnToPipeline(rpn []Token) string {
 		case Var, Num:
 			stack = append(stack, tok.value)
 
+		case Str:
+			stack = append(stack, strconv.Quote(tok.value))
+
+		case Call:
+			parts := strings.Split(tok.value, callArgSep)
+			name, args := parts[0], parts[1:]
+			compiled := make([]string, len(args))
+			for i, a := range args {
+				compiled[i] = compileExpr(a)
+			}
+			stack = append(stack, formatCall(name, compiled))
+
+		case Tern:
+			// tok.value is "tern(condStr,trueStr,falseStr)"
+			inner := strings.TrimSuffix(strings.TrimPrefix(tok.value, "tern("), ")")
+			branches := splitTopLevel(inner, ',')
+			if len(branches) != 3 {
+				return "", fmt.Errorf("malformed ternary expression")
+			}
+			cond := compileExpr(branches[0])
+			whenTrue := compileExpr(branches[1])
+			whenFalse := compileExpr(branches[2])
+			stack = append(stack, fmt.Sprintf("tern %s %s %s", wrapIfNeeded(cond), wrapIfNeeded(whenTrue), wrapIfNeeded(whenFalse)))
+
 		case Op:
-			fn, ok := opMap[tok.value]
+			hadOp = true
+			def, ok := lookupOperator(tok.value)
 			if !ok {
-				// unknown operator → fallback
-				return original
+				return "", fmt.Errorf("unknown operator %q", tok.value)
 			}
+			fn := def.pipeName
 
 			// Unary operator
-			if tok.value == "!" {
+			if def.arity == 1 {
 				if len(stack) < 1 {
-					return original
+					return "", fmt.Errorf("operator %q: not enough operands", tok.value)
 				}
 				a := stack[len(stack)-1]
 				stack = stack[:len(stack)-1]
@@ -264,25 +543,79 @@ func rpnToPipeline(rpn []Token) string {
 
 			// Binary operator
 			if len(stack) < 2 {
-				return original
+				return "", fmt.Errorf("operator %q: not enough operands", tok.value)
 			}
 			b := stack[len(stack)-1]
 			a := stack[len(stack)-2]
 			stack = stack[:len(stack)-2]
 
-			stack = append(stack, fmt.Sprintf("%s | %s %s", a, fn, b))
+			stack = append(stack, fmt.Sprintf("%s | %s %s", a, fn, wrapIfNeeded(b)))
 
 		default:
-			return original
+			return "", fmt.Errorf("unexpected token in expression")
 		}
 	}
 
-	// Invalid RPN
 	if len(stack) != 1 {
-		return original
+		// No operator ever combined two operands: this wasn't an
+		// expression at all, just an ordinary multi-argument template
+		// action like `thousand "." 2 "," $x` that needsPreprocessing
+		// routed through the tokenizer because of its quotes. Pass it
+		// through as the space-separated action it already was instead
+		// of reporting it as malformed.
+		if !hadOp && len(stack) > 1 {
+			return strings.Join(stack, " "), nil
+		}
+		return "", fmt.Errorf("malformed expression")
+	}
+
+	return stack[0], nil
+}
+
+// compileExpr runs a raw sub-expression (a Call argument or Tern branch)
+// through the same tokenize/parseTernary/toRPN/rpnToPipeline pipeline as
+// the top-level expression, so nested calls, strings, and ternaries work
+// anywhere an operand is expected.
+func compileExpr(raw string) string {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return raw
 	}
+	tokens := tokenize(raw)
+	tokens = parseTernary(tokens)
+	rpn := toRPN(tokens)
+	return rpnToPipeline(rpn)
+}
+
+// formatCall renders a function call given its already-compiled argument
+// pipelines. A single argument is piped into the function, matching how
+// every other pipe in this package treats its piped-in value as the
+// subject; zero or multiple arguments are passed as a flat call, matching
+// the order the user wrote them in.
+func formatCall(name string, args []string) string {
+	switch len(args) {
+	case 0:
+		return name
+	case 1:
+		return fmt.Sprintf("%s | %s", args[0], name)
+	default:
+		parts := make([]string, 0, len(args)+1)
+		parts = append(parts, name)
+		for _, a := range args {
+			parts = append(parts, wrapIfNeeded(a))
+		}
+		return strings.Join(parts, " ")
+	}
+}
 
-	return stack[0]
+// wrapIfNeeded parens an expression fragment that contains a space (i.e.
+// is itself a pipeline or multi-arg call), so it reads as one argument
+// when spliced into a flat call or pipe.
+func wrapIfNeeded(s string) string {
+	if strings.ContainsAny(s, " \t") {
+		return "(" + s + ")"
+	}
+	return s
 }
 
 // splitTopLevel splits s by sep but only at top level (not inside parentheses)
@@ -341,6 +674,46 @@ func replaceVars(expr string) string {
 	return out.String()
 }
 
+// needsPreprocessing reports whether expr has any character outside a
+// bare variable/number reference, i.e. it contains an operator, a
+// function call, a string literal, or anything else the tokenizer needs
+// to rewrite into a pipeline. This is character-class based (rather than
+// a fixed operator charset) so a RegisterOperator'd symbol using a new
+// character still routes through the tokenizer.
+func needsPreprocessing(expr string) bool {
+	for _, r := range expr {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.IsSpace(r) || r == '_' || r == '.' || r == '$' {
+			continue
+		}
+		return true
+	}
+	return false
+}
+
+// isBareAction reports whether expr is an ordinary space-separated
+// template action - a registered pipe name invoked directly, the way
+// Number/Currency document their locale-name-first argument ("thousand
+// \".\" 2 \",\" $x", "currency \"de\" $x") - rather than an expression
+// built from operators. Those calls have no operator to apply precedence
+// to, so they're passed through untouched instead of being routed
+// through tokenize/toRPN, which only understands operator-combined
+// operands.
+func isBareAction(expr string) bool {
+	i := 0
+	n := len(expr)
+	if i >= n || !(unicode.IsLetter(rune(expr[i])) || expr[i] == '_') {
+		return false
+	}
+	start := i
+	for i < n && (unicode.IsLetter(rune(expr[i])) || unicode.IsDigit(rune(expr[i])) || expr[i] == '_') {
+		i++
+	}
+	if i >= n || !unicode.IsSpace(rune(expr[i])) {
+		return false
+	}
+	return pipeRegistered(expr[start:i])
+}
+
 // Preprocessor: replace $vars, then convert expressions to pipeline if they contain ops
 func Preprocessor(tmpl string) string {
 	start := 0
@@ -365,8 +738,11 @@ func Preprocessor(tmpl string) string {
 		// 1) replace $vars -> .var.xxx
 		expr = replaceVars(expr)
 
-		// 2) if expression contains operators, parse and convert to pipeline
-		if strings.ContainsAny(expr, "+-*/><=!&|?:") {
+		// 2) if expression contains operators or a function call, parse and convert to pipeline.
+		// A bare call like `thousand "." 2 "," $x` also trips needsPreprocessing
+		// (its quotes aren't in the bare-var charset) but has no operator to
+		// apply precedence to, so it's excluded and passed through as-is.
+		if needsPreprocessing(expr) && !isBareAction(expr) {
 
 			tokens := tokenize(expr)
 			tokens = parseTernary(tokens)
@@ -0,0 +1,30 @@
+package pipe
+
+import "testing"
+
+// TestCompoundConditionsKeepGrouping guards against the binary-op emission
+// regression where a sub-expression on the right of && or a comparison
+// lost its grouping and silently evaluated left-to-right instead.
+func TestCompoundConditionsKeepGrouping(t *testing.T) {
+	cases := []struct {
+		tmpl string
+		data map[string]any
+		want string
+	}{
+		{`{{ $a == 10 && $b == 4 ? "yes" : "no" }}`, map[string]any{"a": 10.0, "b": 4.0}, "yes"},
+		{`{{ $a + 2 > $b + 1 ? "big" : "small" }}`, map[string]any{"a": 10.0, "b": 4.0}, "big"},
+	}
+	for _, c := range cases {
+		tpl, err := Compile("compound-"+c.tmpl, c.tmpl)
+		if err != nil {
+			t.Fatalf("%s: Compile: %v", c.tmpl, err)
+		}
+		out, err := tpl.ExecuteString(map[string]any{"Vars": c.data})
+		if err != nil {
+			t.Fatalf("%s: ExecuteString: %v", c.tmpl, err)
+		}
+		if out != c.want {
+			t.Fatalf("%s: got %q, want %q", c.tmpl, out, c.want)
+		}
+	}
+}
@@ -0,0 +1,192 @@
+package pipe
+
+import (
+	"fmt"
+	"html/template"
+	"reflect"
+	"sort"
+	"sync"
+)
+
+// Assoc is the associativity of a registered infix operator: whether
+// "a op b op c" groups as "(a op b) op c" (LeftAssoc) or "a op (b op c)"
+// (RightAssoc).
+type Assoc int
+
+const (
+	LeftAssoc Assoc = iota
+	RightAssoc
+)
+
+// operatorDef is how an operator symbol rewrites into a pipe call: which
+// pipe it invokes, its precedence and associativity in the shunting-yard
+// parser, and whether it's unary (arity 1) or binary (arity 2).
+type operatorDef struct {
+	pipeName string
+	prec     int
+	assoc    Assoc
+	arity    int
+}
+
+// DuplicateError is returned by RegisterPipe and RegisterOperator when the
+// name or symbol is already registered.
+type DuplicateError struct {
+	Kind string // "pipe" or "operator"
+	Name string
+}
+
+func (e *DuplicateError) Error() string {
+	return fmt.Sprintf("pipe: %s %q is already registered", e.Kind, e.Name)
+}
+
+// mu guards both functions and operators, so that New/Snapshot never
+// observe one mid-update while the other is untouched.
+var (
+	mu        sync.RWMutex
+	functions = template.FuncMap{}
+	operators = map[string]operatorDef{}
+)
+
+func init() {
+	seed := []struct {
+		sym, pipeName string
+		prec          int
+		assoc         Assoc
+		arity         int
+	}{
+		{"!", "not", 9, LeftAssoc, 1},
+		{"*", "mul", 8, LeftAssoc, 2},
+		{"/", "div", 8, LeftAssoc, 2},
+		{"%", "mod", 8, LeftAssoc, 2},
+		{"+", "add", 7, LeftAssoc, 2},
+		{"-", "sub", 7, LeftAssoc, 2},
+		{"<<", "shl", 6, LeftAssoc, 2},
+		{">>", "shr", 6, LeftAssoc, 2},
+		{">", "gt", 5, LeftAssoc, 2},
+		{"<", "lt", 5, LeftAssoc, 2},
+		{">=", "gte", 5, LeftAssoc, 2},
+		{"<=", "lte", 5, LeftAssoc, 2},
+		{"==", "eq", 5, LeftAssoc, 2},
+		{"!=", "ne", 5, LeftAssoc, 2},
+		{"&", "band", 4, LeftAssoc, 2},
+		{"^", "xor", 3, LeftAssoc, 2},
+		{"|", "bor", 2, LeftAssoc, 2},
+		{"&&", "and", 1, LeftAssoc, 2},
+		{"||", "or", 0, LeftAssoc, 2},
+		{"??", "nullish", -1, LeftAssoc, 2},
+	}
+	for _, s := range seed {
+		operators[s.sym] = operatorDef{pipeName: s.pipeName, prec: s.prec, assoc: s.assoc, arity: s.arity}
+	}
+}
+
+// RegisterPipe adds a new pipe to the global registry under name. fn must
+// be a function, in the same style as Thousand/Add: typically
+// func(...reflect.Value) reflect.Value, though any text/template-callable
+// function works. RegisterPipe returns a *DuplicateError if name is
+// already registered; use Unregister first to replace one.
+func RegisterPipe(name string, fn any) error {
+	if name == "" {
+		return fmt.Errorf("pipe: register pipe: name must not be empty")
+	}
+	if reflect.ValueOf(fn).Kind() != reflect.Func {
+		return fmt.Errorf("pipe: register pipe %q: fn must be a function", name)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if _, exists := functions[name]; exists {
+		return &DuplicateError{Kind: "pipe", Name: name}
+	}
+	functions[name] = fn
+	return nil
+}
+
+// RegisterOperator adds a new infix (or, with arity 1, prefix) operator
+// symbol to the expression preprocessor, rewriting it to a call to the
+// pipe named pipeName. prec and assoc control how it binds relative to
+// the built-in operators — see the precedence table seeded in init() for
+// reference points. RegisterOperator returns a *DuplicateError if sym is
+// already registered.
+func RegisterOperator(sym, pipeName string, prec int, assoc Assoc, arity int) error {
+	if sym == "" {
+		return fmt.Errorf("pipe: register operator: symbol must not be empty")
+	}
+	if arity != 1 && arity != 2 {
+		return fmt.Errorf("pipe: register operator %q: arity must be 1 or 2, got %d", sym, arity)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if _, exists := operators[sym]; exists {
+		return &DuplicateError{Kind: "operator", Name: sym}
+	}
+	operators[sym] = operatorDef{pipeName: pipeName, prec: prec, assoc: assoc, arity: arity}
+	return nil
+}
+
+// Unregister removes a pipe from the global registry. It is a no-op if
+// name isn't registered.
+func Unregister(name string) {
+	mu.Lock()
+	defer mu.Unlock()
+	delete(functions, name)
+}
+
+// Snapshot returns a copy of the global pipe registry, safe to use or
+// mutate independently of concurrent RegisterPipe/Unregister calls.
+func Snapshot() template.FuncMap {
+	mu.RLock()
+	defer mu.RUnlock()
+	out := template.FuncMap{}
+	for k, v := range functions {
+		out[k] = v
+	}
+	return out
+}
+
+// pipeRegistered reports whether name is a registered pipe, for the
+// preprocessor's "|" disambiguation: a bare name found here is a genuine
+// template pipe, not the bor operator.
+func pipeRegistered(name string) bool {
+	mu.RLock()
+	defer mu.RUnlock()
+	_, ok := functions[name]
+	return ok
+}
+
+// lookupOperator resolves an operator symbol's definition under the read
+// lock.
+func lookupOperator(sym string) (operatorDef, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+	def, ok := operators[sym]
+	return def, ok
+}
+
+// matchOperatorSymbol finds the longest registered operator symbol
+// starting at position i in expr, so the tokenizer recognizes
+// RegisterOperator'd symbols of any length without a forked copy of this
+// package.
+func matchOperatorSymbol(expr string, i int) (string, bool) {
+	for _, sym := range operatorSymbolsSorted() {
+		if i+len(sym) <= len(expr) && expr[i:i+len(sym)] == sym {
+			return sym, true
+		}
+	}
+	return "", false
+}
+
+// operatorSymbolsSorted returns the registered operator symbols, longest
+// first, so matchOperatorSymbol always finds the longest match (e.g. "=="
+// before "=").
+func operatorSymbolsSorted() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+	syms := make([]string, 0, len(operators))
+	for s := range operators {
+		syms = append(syms, s)
+	}
+	sort.Slice(syms, func(i, j int) bool { return len(syms[i]) > len(syms[j]) })
+	return syms
+}
@@ -0,0 +1,230 @@
+package pipe
+
+import (
+	"reflect"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// last args are subjects, the same convention Thousand/Add use: optional
+// parameters come first, the piped-in value is always vals[len(vals)-1].
+
+// Upper uppercases a string.
+func Upper(vals ...reflect.Value) reflect.Value {
+	if len(vals) == 0 {
+		return RV("")
+	}
+	return RV(strings.ToUpper(toStringRV(vals[len(vals)-1])))
+}
+
+// Lower lowercases a string.
+func Lower(vals ...reflect.Value) reflect.Value {
+	if len(vals) == 0 {
+		return RV("")
+	}
+	return RV(strings.ToLower(toStringRV(vals[len(vals)-1])))
+}
+
+// Title capitalizes the first letter of each word.
+func Title(vals ...reflect.Value) reflect.Value {
+	if len(vals) == 0 {
+		return RV("")
+	}
+	return RV(titleCase(toStringRV(vals[len(vals)-1])))
+}
+
+// titleCase upper-cases the first letter of every space-separated word
+// and leaves the rest of each word untouched, without pulling in
+// strings.Title (deprecated since Go 1.18, and wrong at non-ASCII word
+// boundaries).
+func titleCase(s string) string {
+	var b strings.Builder
+	atWordStart := true
+	for _, r := range s {
+		if atWordStart && unicode.IsLetter(r) {
+			b.WriteRune(unicode.ToUpper(r))
+		} else {
+			b.WriteRune(r)
+		}
+		atWordStart = unicode.IsSpace(r)
+	}
+	return b.String()
+}
+
+// Trim strips leading and trailing whitespace, or the optional cutset
+// given as vals[0].
+func Trim(vals ...reflect.Value) reflect.Value {
+	if len(vals) == 0 {
+		return RV("")
+	}
+	s := toStringRV(vals[len(vals)-1])
+	if len(vals) > 1 {
+		return RV(strings.Trim(s, toStringRV(vals[0])))
+	}
+	return RV(strings.TrimSpace(s))
+}
+
+// TrimPrefix removes vals[0] from the front of the string if present.
+func TrimPrefix(vals ...reflect.Value) reflect.Value {
+	if len(vals) < 2 {
+		return RV("")
+	}
+	s := toStringRV(vals[len(vals)-1])
+	return RV(strings.TrimPrefix(s, toStringRV(vals[0])))
+}
+
+// TrimSuffix removes vals[0] from the end of the string if present.
+func TrimSuffix(vals ...reflect.Value) reflect.Value {
+	if len(vals) < 2 {
+		return RV("")
+	}
+	s := toStringRV(vals[len(vals)-1])
+	return RV(strings.TrimSuffix(s, toStringRV(vals[0])))
+}
+
+// Replace replaces all occurrences of vals[0] with vals[1] in the string.
+func Replace(vals ...reflect.Value) reflect.Value {
+	if len(vals) < 3 {
+		return RV("")
+	}
+	s := toStringRV(vals[len(vals)-1])
+	old, new := toStringRV(vals[0]), toStringRV(vals[1])
+	return RV(strings.ReplaceAll(s, old, new))
+}
+
+// Split breaks the string into a slice on vals[0].
+func Split(vals ...reflect.Value) reflect.Value {
+	if len(vals) < 2 {
+		return RV([]string{})
+	}
+	s := toStringRV(vals[len(vals)-1])
+	return RV(strings.Split(s, toStringRV(vals[0])))
+}
+
+// Join concatenates a slice with vals[0] as the separator.
+func Join(vals ...reflect.Value) reflect.Value {
+	if len(vals) < 2 {
+		return RV("")
+	}
+	elems := toStringSliceRV(vals[len(vals)-1])
+	return RV(strings.Join(elems, toStringRV(vals[0])))
+}
+
+// Contains reports whether the string contains vals[0].
+func Contains(vals ...reflect.Value) reflect.Value {
+	if len(vals) < 2 {
+		return RV(false)
+	}
+	s := toStringRV(vals[len(vals)-1])
+	return RV(strings.Contains(s, toStringRV(vals[0])))
+}
+
+// HasPrefix reports whether the string starts with vals[0].
+func HasPrefix(vals ...reflect.Value) reflect.Value {
+	if len(vals) < 2 {
+		return RV(false)
+	}
+	s := toStringRV(vals[len(vals)-1])
+	return RV(strings.HasPrefix(s, toStringRV(vals[0])))
+}
+
+// HasSuffix reports whether the string ends with vals[0].
+func HasSuffix(vals ...reflect.Value) reflect.Value {
+	if len(vals) < 2 {
+		return RV(false)
+	}
+	s := toStringRV(vals[len(vals)-1])
+	return RV(strings.HasSuffix(s, toStringRV(vals[0])))
+}
+
+// Repeat repeats the string vals[0] times.
+func Repeat(vals ...reflect.Value) reflect.Value {
+	if len(vals) < 2 {
+		return RV("")
+	}
+	s := toStringRV(vals[len(vals)-1])
+	n := int(toIntRV(vals[0]))
+	if n < 0 {
+		n = 0
+	}
+	return RV(strings.Repeat(s, n))
+}
+
+// Truncate shortens the string to vals[0] runes, appending "..." if it was
+// cut. An optional vals[1] overrides the "..." suffix.
+func Truncate(vals ...reflect.Value) reflect.Value {
+	if len(vals) < 2 {
+		return RV("")
+	}
+	s := toStringRV(vals[len(vals)-1])
+	n := int(toIntRV(vals[0]))
+	suffix := "..."
+	if len(vals) > 2 {
+		suffix = toStringRV(vals[1])
+	}
+	r := []rune(s)
+	if n < 0 || len(r) <= n {
+		return RV(s)
+	}
+	return RV(string(r[:n]) + suffix)
+}
+
+var slugNonAlnum = regexp.MustCompile(`[^a-z0-9]+`)
+
+// Slug lowercases a string and replaces runs of non-alphanumeric characters
+// with a single "-", trimming leading/trailing dashes.
+func Slug(vals ...reflect.Value) reflect.Value {
+	if len(vals) == 0 {
+		return RV("")
+	}
+	s := strings.ToLower(toStringRV(vals[len(vals)-1]))
+	s = slugNonAlnum.ReplaceAllString(s, "-")
+	return RV(strings.Trim(s, "-"))
+}
+
+// PadLeft pads the string on the left to vals[0] runes wide using an
+// optional pad string given as vals[1] (default " ").
+func PadLeft(vals ...reflect.Value) reflect.Value {
+	if len(vals) < 2 {
+		return RV("")
+	}
+	s := toStringRV(vals[len(vals)-1])
+	width := int(toIntRV(vals[0]))
+	pad := " "
+	if len(vals) > 2 {
+		pad = toStringRV(vals[1])
+	}
+	return RV(padString(s, width, pad, true))
+}
+
+// PadRight pads the string on the right to vals[0] runes wide using an
+// optional pad string given as vals[1] (default " ").
+func PadRight(vals ...reflect.Value) reflect.Value {
+	if len(vals) < 2 {
+		return RV("")
+	}
+	s := toStringRV(vals[len(vals)-1])
+	width := int(toIntRV(vals[0]))
+	pad := " "
+	if len(vals) > 2 {
+		pad = toStringRV(vals[1])
+	}
+	return RV(padString(s, width, pad, false))
+}
+
+// padString grows s to width runes by repeating pad on the left or right.
+func padString(s string, width int, pad string, left bool) string {
+	if pad == "" {
+		return s
+	}
+	need := width - len([]rune(s))
+	if need <= 0 {
+		return s
+	}
+	filler := []rune(strings.Repeat(pad, need/len([]rune(pad))+1))[:need]
+	if left {
+		return string(filler) + s
+	}
+	return s + string(filler)
+}